@@ -44,30 +44,11 @@ import (
 
 const (
 	versionPrefix = ".sys.v#."
-	favoritesKey  = "http://owncloud.org/ns/favorite"
+	favoritesKey  = eosclient.FavoritesKey
 )
 
 func serializeAttribute(a *eosclient.Attribute) string {
-	return fmt.Sprintf("%s.%s=%s", attrTypeToString(a.Type), a.Key, a.Val)
-}
-
-func attrTypeToString(at eosclient.AttrType) string {
-	switch at {
-	case eosclient.SystemAttr:
-		return "sys"
-	case eosclient.UserAttr:
-		return "user"
-	default:
-		return "invalid"
-	}
-}
-
-func isValidAttribute(a *eosclient.Attribute) bool {
-	// validate that an attribute is correct.
-	if (a.Type != eosclient.SystemAttr && a.Type != eosclient.UserAttr) || a.Key == "" {
-		return false
-	}
-	return true
+	return fmt.Sprintf("%s=%s", a.GetKey(), a.Val)
 }
 
 // Options to configure the Client.
@@ -463,7 +444,7 @@ func (c *Client) mergeACLsAndAttrsForFiles(ctx context.Context, auth eosclient.A
 
 // SetAttr sets an extended attributes on a path.
 func (c *Client) SetAttr(ctx context.Context, auth eosclient.Authorization, attr *eosclient.Attribute, errorIfExists, recursive bool, path, app string) error {
-	if !isValidAttribute(attr) {
+	if !attr.IsValid() {
 		return errors.New("eos: attr is invalid: " + serializeAttribute(attr))
 	}
 
@@ -478,6 +459,32 @@ func (c *Client) SetAttr(ctx context.Context, auth eosclient.Authorization, attr
 	return c.setEOSAttr(ctx, auth, attr, errorIfExists, recursive, path, app)
 }
 
+// SetAttrs sets several extended attributes on a path.
+// The eos binary only supports setting one attribute per command,
+// so the attributes are validated upfront and then set one by one.
+// This is not atomic: if setting an attribute fails, e.g. because it
+// already exists and errorIfExists is set, the attributes set before
+// it are left in place.
+func (c *Client) SetAttrs(ctx context.Context, auth eosclient.Authorization, attrs []*eosclient.Attribute, errorIfExists, recursive bool, path, app string) error {
+	seen := make(map[string]bool, len(attrs))
+	for _, attr := range attrs {
+		if !attr.IsValid() {
+			return errors.New("eos: attr is invalid: " + serializeAttribute(attr))
+		}
+		if seen[attr.GetKey()] {
+			return errors.New("eos: duplicate attr: " + attr.GetKey())
+		}
+		seen[attr.GetKey()] = true
+	}
+
+	for _, attr := range attrs {
+		if err := c.SetAttr(ctx, auth, attr, errorIfExists, recursive, path, app); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Client) setEOSAttr(ctx context.Context, auth eosclient.Authorization, attr *eosclient.Attribute, errorIfExists, recursive bool, path, app string) error {
 	args := []string{}
 	if app != "" {
@@ -546,7 +553,7 @@ func (c *Client) UnsetAttr(ctx context.Context, auth eosclient.Authorization, at
 
 // UnsetAttr unsets an extended attribute on a path.
 func (c *Client) unsetEOSAttr(ctx context.Context, auth eosclient.Authorization, attr *eosclient.Attribute, recursive bool, path, app string, deleteFavs bool) error {
-	if !isValidAttribute(attr) {
+	if !attr.IsValid() {
 		return errors.New("eos: attr is invalid: " + serializeAttribute(attr))
 	}
 
@@ -568,7 +575,7 @@ func (c *Client) unsetEOSAttr(ctx context.Context, auth eosclient.Authorization,
 	if recursive {
 		args = append(args, "-r")
 	}
-	args = append(args, "rm", fmt.Sprintf("%s.%s", attrTypeToString(attr.Type), attr.Key), path)
+	args = append(args, "rm", attr.GetKey(), path)
 
 	_, _, err = c.executeEOS(ctx, args, auth)
 	if err != nil {
@@ -622,17 +629,13 @@ func deserializeAttribute(attrStr string) (*eosclient.Attribute, error) {
 	if len(keyValue) != 2 {
 		return nil, errtypes.InternalError("wrong attr format to deserialize")
 	}
-	type2key := strings.SplitN(keyValue[0], ".", 2) // type2key = ["sys", "forced.checksum"]
-	if len(type2key) != 2 {
-		return nil, errtypes.InternalError("wrong attr format to deserialize")
-	}
-	t, err := eosclient.AttrStringToType(type2key[0])
+	attr, err := eosclient.ParseAttribute(keyValue[0])
 	if err != nil {
 		return nil, err
 	}
 	// trim \" from value
-	value := strings.Trim(keyValue[1], "\"")
-	return &eosclient.Attribute{Type: t, Key: type2key[1], Val: value}, nil
+	attr.Val = strings.Trim(keyValue[1], "\"")
+	return attr, nil
 }
 
 // GetQuota gets the quota of a user on the quota node defined by path.
@@ -38,6 +38,7 @@ type EOSClient interface {
 	GetFileInfoByFXID(ctx context.Context, auth Authorization, fxid string) (*FileInfo, error)
 	GetFileInfoByPath(ctx context.Context, auth Authorization, path string) (*FileInfo, error)
 	SetAttr(ctx context.Context, auth Authorization, attr *Attribute, errorIfExists, recursive bool, path, app string) error
+	SetAttrs(ctx context.Context, auth Authorization, attrs []*Attribute, errorIfExists, recursive bool, path, app string) error
 	UnsetAttr(ctx context.Context, auth Authorization, attr *Attribute, recursive bool, path, app string) error
 	GetAttr(ctx context.Context, auth Authorization, key, path string) (*Attribute, error)
 	GetAttrs(ctx context.Context, auth Authorization, path string) ([]*Attribute, error)
@@ -62,7 +63,7 @@ type EOSClient interface {
 }
 
 // AttrType is the type of extended attribute,
-// either system (sys) or user (user).
+// either system (sys), user (user) or trusted (trusted).
 type AttrType uint32
 
 // Attribute represents an EOS extended attribute.
@@ -71,6 +72,9 @@ type Attribute struct {
 	Key, Val string
 }
 
+// FavoritesKey is the key of the user attribute holding the per-user favorites.
+const FavoritesKey = "http://owncloud.org/ns/favorite"
+
 // FileInfo represents the metadata information returned by querying the EOS namespace.
 type FileInfo struct {
 	IsDir      bool
@@ -140,10 +144,22 @@ type Role struct {
 }
 
 // Authorization specifies the mechanisms through which EOS can be accessed.
-// One of the data members must be set.
+// One of Role or Token must be set.
 type Authorization struct {
 	Role  Role
 	Token string
+	// Instance optionally identifies the EOS instance the request
+	// is meant for, in setups where several instances are served.
+	// The clients do not route on it: it is up to the caller to pick
+	// the client configured for that instance.
+	Instance string
+}
+
+// WithInstance returns a copy of the auth object targeting the given instance,
+// e.g. eosclient.GetDaemonAuth().WithInstance("eoshome").
+func (a Authorization) WithInstance(instance string) Authorization {
+	a.Instance = instance
+	return a
 }
 
 // AttrAlreadyExistsError is the error raised when setting
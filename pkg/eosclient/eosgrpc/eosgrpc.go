@@ -48,7 +48,7 @@ import (
 
 const (
 	versionPrefix = ".sys.v#."
-	favoritesKey  = "http://owncloud.org/ns/favorite"
+	favoritesKey  = eosclient.FavoritesKey
 )
 
 const (
@@ -131,26 +131,7 @@ func (opt *Options) init() {
 }
 
 func serializeAttribute(a *eosclient.Attribute) string {
-	return fmt.Sprintf("%s.%s=%s", attrTypeToString(a.Type), a.Key, a.Val)
-}
-
-func attrTypeToString(at eosclient.AttrType) string {
-	switch at {
-	case eosclient.SystemAttr:
-		return "sys"
-	case eosclient.UserAttr:
-		return "user"
-	default:
-		return "invalid"
-	}
-}
-
-func isValidAttribute(a *eosclient.Attribute) bool {
-	// validate that an attribute is correct.
-	if (a.Type != eosclient.SystemAttr && a.Type != eosclient.UserAttr) || a.Key == "" {
-		return false
-	}
-	return true
+	return fmt.Sprintf("%s=%s", a.GetKey(), a.Val)
 }
 
 // Create and connect a grpc eos Client.
@@ -517,7 +498,7 @@ func (c *Client) fixupACLs(ctx context.Context, auth eosclient.Authorization, in
 
 // SetAttr sets an extended attributes on a path.
 func (c *Client) SetAttr(ctx context.Context, auth eosclient.Authorization, attr *eosclient.Attribute, errorIfExists, recursive bool, path, app string) error {
-	if !isValidAttribute(attr) {
+	if !attr.IsValid() {
 		return errors.New("eos: attr is invalid: " + serializeAttribute(attr))
 	}
 
@@ -532,9 +513,53 @@ func (c *Client) SetAttr(ctx context.Context, auth eosclient.Authorization, attr
 	return c.setEOSAttr(ctx, auth, attr, errorIfExists, recursive, path, app)
 }
 
+// SetAttrs sets several extended attributes on a path.
+// All of them are sent in a single request.
+// The only exception are favorites, which are stored per user and
+// are set one by one after the other attributes.
+func (c *Client) SetAttrs(ctx context.Context, auth eosclient.Authorization, attrs []*eosclient.Attribute, errorIfExists, recursive bool, path, app string) error {
+	seen := make(map[string]bool, len(attrs))
+	for _, attr := range attrs {
+		if !attr.IsValid() {
+			return errors.New("eos: attr is invalid: " + serializeAttribute(attr))
+		}
+		if seen[attr.GetKey()] {
+			return errors.New("eos: duplicate attr: " + attr.GetKey())
+		}
+		seen[attr.GetKey()] = true
+	}
+
+	var favs, eosAttrs []*eosclient.Attribute
+	for _, attr := range attrs {
+		if attr.Type == eosclient.UserAttr && attr.Key == favoritesKey {
+			favs = append(favs, attr)
+		} else {
+			eosAttrs = append(eosAttrs, attr)
+		}
+	}
+
+	if len(eosAttrs) > 0 {
+		if err := c.setEOSAttrs(ctx, "SetAttrs", auth, eosAttrs, errorIfExists, recursive, path, app); err != nil {
+			return err
+		}
+	}
+	for _, attr := range favs {
+		if err := c.SetAttr(ctx, auth, attr, errorIfExists, recursive, path, app); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Client) setEOSAttr(ctx context.Context, auth eosclient.Authorization, attr *eosclient.Attribute, errorIfExists, recursive bool, path, app string) error {
+	return c.setEOSAttrs(ctx, "SetAttr", auth, []*eosclient.Attribute{attr}, errorIfExists, recursive, path, app)
+}
+
+// setEOSAttrs sets the given attributes with a single request,
+// logging under the name of the calling function fn.
+func (c *Client) setEOSAttrs(ctx context.Context, fn string, auth eosclient.Authorization, attrs []*eosclient.Attribute, errorIfExists, recursive bool, path, app string) error {
 	log := appctx.GetLogger(ctx)
-	log.Info().Str("func", "SetAttr").Str("uid,gid", auth.Role.UID+","+auth.Role.GID).Str("path", path).Msg("")
+	log.Info().Str("func", fn).Str("uid,gid", auth.Role.UID+","+auth.Role.GID).Str("path", path).Int("nattrs", len(attrs)).Msg("")
 
 	// Initialize the common fields of the NSReq
 	rq, err := c.initNSRequest(ctx, auth, app)
@@ -544,7 +569,10 @@ func (c *Client) setEOSAttr(ctx context.Context, auth eosclient.Authorization, a
 
 	msg := new(erpc.NSRequest_SetXAttrRequest)
 
-	var m = map[string][]byte{attr.GetKey(): []byte(attr.Val)}
+	var m = make(map[string][]byte, len(attrs))
+	for _, attr := range attrs {
+		m[attr.GetKey()] = []byte(attr.Val)
+	}
 	msg.Xattrs = m
 	msg.Recursive = recursive
 
@@ -566,7 +594,7 @@ func (c *Client) setEOSAttr(ctx context.Context, auth eosclient.Authorization, a
 	}
 
 	if e != nil {
-		log.Error().Str("func", "SetAttr").Str("path", path).Str("err", e.Error()).Msg("")
+		log.Error().Str("func", fn).Str("path", path).Str("err", e.Error()).Msg("")
 		return e
 	}
 
@@ -575,7 +603,7 @@ func (c *Client) setEOSAttr(ctx context.Context, auth eosclient.Authorization, a
 	}
 
 	if resp.GetError() != nil {
-		log.Error().Str("func", "setAttr").Str("path", path).Int64("errcode", resp.GetError().Code).Str("errmsg", resp.GetError().Msg).Msg("EOS negative result")
+		log.Error().Str("func", strings.ToLower(fn[:1])+fn[1:]).Str("path", path).Int64("errcode", resp.GetError().Code).Str("errmsg", resp.GetError().Msg).Msg("EOS negative result")
 	}
 
 	return err
@@ -710,19 +738,11 @@ func (c *Client) GetAttrs(ctx context.Context, auth eosclient.Authorization, pat
 
 func getAttribute(key, val string) (*eosclient.Attribute, error) {
 	// key is in the form sys.forced.checksum
-	type2key := strings.SplitN(key, ".", 2) // type2key = ["sys", "forced.checksum"]
-	if len(type2key) != 2 {
-		return nil, errtypes.InternalError("wrong attr format to deserialize")
-	}
-	t, err := eosclient.AttrStringToType(type2key[0])
+	attr, err := eosclient.ParseAttribute(key)
 	if err != nil {
 		return nil, err
 	}
-	attr := &eosclient.Attribute{
-		Type: t,
-		Key:  type2key[1],
-		Val:  val,
-	}
+	attr.Val = val
 	return attr, nil
 }
 
@@ -734,7 +754,7 @@ func (c *Client) GetFileInfoByPath(ctx context.Context, userAuth eosclient.Autho
 	// UserAuth may not be sufficient, because the user may not have access to the file
 	// e.g. in the case of a guest account. So we check if a uid/gid is set, and if not,
 	// revert to the daemon account
-	auth := eosclient.GetUserOrDaemonAuth(userAuth)
+	auth := eosclient.GetUserOrDaemonAuthWithContext(ctx, userAuth)
 
 	// Initialize the common fields of the MDReq
 	mdrq, err := c.initMDRequest(ctx, auth)
@@ -973,11 +993,12 @@ func (c *Client) Chown(ctx context.Context, auth, chownAuth eosclient.Authorizat
 	msg := new(erpc.NSRequest_ChownRequest)
 	msg.Owner = new(erpc.RoleId)
 
-	uid, gid, err := eosclient.ExtractUidGid(chownAuth)
-	if err == nil {
-		msg.Owner.Uid = uid
-		msg.Owner.Gid = gid
+	uid, gid, err := eosclient.ExtractUidGidStrict(chownAuth)
+	if err != nil {
+		return errors.Wrap(err, "Failed to extract uid/gid from chown auth")
 	}
+	msg.Owner.Uid = uid
+	msg.Owner.Gid = gid
 
 	msg.Id = new(erpc.MDId)
 	msg.Id.Path = []byte(path)
@@ -1221,7 +1242,7 @@ func (c *Client) List(ctx context.Context, auth eosclient.Authorization, dpath s
 
 	fdrq.Role = new(erpc.RoleId)
 
-	uid, gid, err := eosclient.ExtractUidGid(auth)
+	uid, gid, err := eosclient.ExtractUidGidStrict(auth)
 	if err == nil {
 		fdrq.Role.Uid = uid
 		fdrq.Role.Gid = gid
@@ -0,0 +1,152 @@
+// Copyright 2018-2025 CERN
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// In applying this license, CERN does not waive the privileges and immunities
+// granted to it by virtue of its status as an Intergovernmental Organization
+// or submit itself to any jurisdiction.
+
+package eosgrpc
+
+import (
+	"context"
+	"testing"
+
+	erpc "github.com/cern-eos/go-eosgrpc"
+	"github.com/cs3org/eos-reva-plugin/pkg/eosclient"
+	"google.golang.org/grpc"
+)
+
+// fakeEosClient records the namespace requests it receives.
+// Any other method of the EOS gRPC interface panics.
+type fakeEosClient struct {
+	erpc.EosClient
+	execs []*erpc.NSRequest
+	resp  *erpc.NSResponse
+}
+
+func (c *fakeEosClient) Exec(ctx context.Context, in *erpc.NSRequest, opts ...grpc.CallOption) (*erpc.NSResponse, error) {
+	c.execs = append(c.execs, in)
+	if c.resp != nil {
+		return c.resp, nil
+	}
+	return &erpc.NSResponse{}, nil
+}
+
+func newFakeClient(resp *erpc.NSResponse) (*Client, *fakeEosClient) {
+	fake := &fakeEosClient{resp: resp}
+	return &Client{opt: &Options{Authkey: "secret"}, cl: fake}, fake
+}
+func TestSetAttrsSingleExec(t *testing.T) {
+	c, fake := newFakeClient(nil)
+	attrs := []*eosclient.Attribute{
+		{Type: eosclient.SystemAttr, Key: "acl", Val: "u:1000=rwx"},
+		{Type: eosclient.UserAttr, Key: "ocis.foo", Val: "bar"},
+		{Type: eosclient.TrustedAttr, Key: "ocis.blobid", Val: "abc123"},
+	}
+
+	auth := eosclient.Authorization{Role: eosclient.Role{UID: "1000", GID: "1000"}}
+	if err := c.SetAttrs(context.Background(), auth, attrs, false, false, "/eos/user/e/einstein/file", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(fake.execs) != 1 {
+		t.Fatalf("expected a single Exec call, got %d", len(fake.execs))
+	}
+	xattrs := fake.execs[0].GetXattr().GetXattrs()
+	expected := map[string]string{
+		"sys.acl":             "u:1000=rwx",
+		"user.ocis.foo":       "bar",
+		"trusted.ocis.blobid": "abc123",
+	}
+	if len(xattrs) != len(expected) {
+		t.Fatalf("expected %d xattrs, got %v", len(expected), xattrs)
+	}
+	for k, v := range expected {
+		if string(xattrs[k]) != v {
+			t.Fatalf("expected %s=%s, got %v", k, v, xattrs)
+		}
+	}
+}
+
+func TestSetAttrsRejectsInvalidBeforeExec(t *testing.T) {
+	tests := map[string][]*eosclient.Attribute{
+		"missing key": {
+			{Type: eosclient.UserAttr, Key: "ocis.foo", Val: "bar"},
+			{Type: eosclient.UserAttr, Val: "no key"},
+		},
+		"duplicate key": {
+			{Type: eosclient.UserAttr, Key: "ocis.foo", Val: "bar"},
+			{Type: eosclient.UserAttr, Key: "ocis.foo", Val: "baz"},
+		},
+	}
+
+	for name, attrs := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fake := newFakeClient(nil)
+			if err := c.SetAttrs(context.Background(), eosclient.GetEmptyAuth(), attrs, false, false, "/eos/file", ""); err == nil {
+				t.Fatal("expected error for invalid attributes")
+			}
+			if len(fake.execs) != 0 {
+				t.Fatalf("expected no Exec call, got %d", len(fake.execs))
+			}
+		})
+	}
+}
+
+func TestUnsetAttrKeyAndIdempotency(t *testing.T) {
+	tests := map[string]struct {
+		attr *eosclient.Attribute
+		key  string
+	}{
+		"sys":     {attr: &eosclient.Attribute{Type: eosclient.SystemAttr, Key: "acl"}, key: "sys.acl"},
+		"user":    {attr: &eosclient.Attribute{Type: eosclient.UserAttr, Key: "ocis.foo"}, key: "user.ocis.foo"},
+		"trusted": {attr: &eosclient.Attribute{Type: eosclient.TrustedAttr, Key: "ocis.foo"}, key: "trusted.ocis.foo"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the first removal succeeds, the second one finds the attribute gone (ENODATA)
+			c, fake := newFakeClient(nil)
+			if err := eosclient.UnsetAttr(context.Background(), c, eosclient.GetEmptyAuth(), tt.attr, "/eos/file"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			fake.resp = &erpc.NSResponse{Error: &erpc.NSResponse_ErrorResponse{Code: 61, Msg: "no such attribute"}}
+			if err := eosclient.UnsetAttr(context.Background(), c, eosclient.GetEmptyAuth(), tt.attr, "/eos/file"); err != nil {
+				t.Fatalf("expected removing a missing attribute to succeed, got %v", err)
+			}
+
+			if len(fake.execs) != 2 {
+				t.Fatalf("expected 2 Exec calls, got %d", len(fake.execs))
+			}
+			for _, rq := range fake.execs {
+				keys := rq.GetXattr().GetKeystodelete()
+				if len(keys) != 1 || keys[0] != tt.key {
+					t.Fatalf("expected key %s, got %v", tt.key, keys)
+				}
+			}
+		})
+	}
+}
+
+func TestChownRejectsMalformedAuth(t *testing.T) {
+	c, fake := newFakeClient(nil)
+	chownAuth := eosclient.Authorization{Role: eosclient.Role{UID: "einstein", GID: "1000"}}
+
+	if err := c.Chown(context.Background(), eosclient.GetDaemonAuth(), chownAuth, "/eos/file"); err == nil {
+		t.Fatal("expected error for malformed chown auth")
+	}
+	if len(fake.execs) != 0 {
+		t.Fatalf("expected no Exec call, got %d", len(fake.execs))
+	}
+}
@@ -19,17 +19,36 @@
 package eosclient
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
+	"github.com/cs3org/reva/pkg/appctx"
 	"github.com/cs3org/reva/pkg/errtypes"
+	"github.com/cs3org/reva/pkg/storage/utils/acl"
 )
 
+// logDaemonFallback enables debug logging of the cases where
+// GetUserOrDaemonAuthWithContext falls back to the daemon account.
+var logDaemonFallback atomic.Bool
+
+// SetLogDaemonFallback enables or disables debug logging of the cases where
+// GetUserOrDaemonAuthWithContext falls back to the daemon account.
+func SetLogDaemonFallback(enabled bool) {
+	logDaemonFallback.Store(enabled)
+}
+
 const (
 	// SystemAttr is the system extended attribute.
 	SystemAttr AttrType = iota
 	// UserAttr is the user extended attribute.
 	UserAttr
+	// TrustedAttr is the trusted extended attribute.
+	TrustedAttr
 )
 
 // AttrStringToType converts a string to an AttrType.
@@ -39,6 +58,8 @@ func AttrStringToType(t string) (AttrType, error) {
 		return SystemAttr, nil
 	case "user":
 		return UserAttr, nil
+	case "trusted":
+		return TrustedAttr, nil
 	default:
 		return 0, errtypes.InternalError("attr type not existing")
 	}
@@ -51,6 +72,8 @@ func AttrTypeToString(at AttrType) string {
 		return "sys"
 	case UserAttr:
 		return "user"
+	case TrustedAttr:
+		return "trusted"
 	default:
 		return "invalid"
 	}
@@ -61,8 +84,142 @@ func (a *Attribute) GetKey() string {
 	return fmt.Sprintf("%s.%s", AttrTypeToString(a.Type), a.Key)
 }
 
+// IsValid reports whether the attribute has a key and a known type.
+func (a *Attribute) IsValid() bool {
+	return a.Key != "" && AttrTypeToString(a.Type) != "invalid"
+}
+
+// ParseAttribute parses a fully qualified attribute key, e.g. sys.acl,
+// into an Attribute with the corresponding type and key.
+// It is the inverse of GetKey.
+func ParseAttribute(full string) (*Attribute, error) {
+	type2key := strings.SplitN(full, ".", 2) // type2key = ["sys", "forced.checksum"]
+	if len(type2key) != 2 || type2key[1] == "" {
+		return nil, errtypes.InternalError(fmt.Sprintf("attr %q is not in the form <type>.<key>", full))
+	}
+	t, err := AttrStringToType(type2key[0])
+	if err != nil {
+		return nil, err
+	}
+	return &Attribute{Type: t, Key: type2key[1]}, nil
+}
+
+// ACLTypeOther is the type of the EOS ACL entries applying to everybody,
+// which carry no qualifier, e.g. z:rx.
+const ACLTypeOther = "z"
+
+// ParseACL parses an EOS sys.acl attribute value, either in the stored
+// form u:1000:rwx!d,egroup:my-group:rx or in the u:1000=rwx form written
+// by AddACL. On top of acl.Parse, it accepts entries without qualifier, e.g. z:rx.
+func ParseACL(s string) (*acl.ACLs, error) {
+	entries := []*acl.Entry{}
+	for _, t := range strings.Split(s, acl.ShortTextForm) {
+		if perm, ok := strings.CutPrefix(t, ACLTypeOther+":"); ok && !strings.ContainsAny(perm, ":=") {
+			if perm == "" {
+				return nil, errtypes.InternalError(fmt.Sprintf("invalid eos acl entry %q", t))
+			}
+			entries = append(entries, &acl.Entry{Type: ACLTypeOther, Permissions: perm})
+			continue
+		}
+		a, err := acl.Parse(t, acl.ShortTextForm)
+		if err != nil {
+			return nil, errtypes.InternalError(fmt.Sprintf("invalid eos acl entry %q", t))
+		}
+		entries = append(entries, a.Entries...)
+	}
+	return &acl.ACLs{Entries: entries}, nil
+}
+
+// SerializeACL serializes the ACLs in the form EOS stores them:
+// type:qualifier:perms, type:perms for z and lw:id=perms for
+// lightweight accounts. The result can be parsed back with ParseACL.
+func SerializeACL(a *acl.ACLs) string {
+	sysACL := []string{}
+	for _, e := range a.Entries {
+		switch {
+		case e.Type == ACLTypeOther && e.Qualifier == "":
+			sysACL = append(sysACL, ACLTypeOther+":"+e.Permissions)
+		case e.Type == acl.TypeLightweight:
+			sysACL = append(sysACL, e.CitrineSerialize())
+		default:
+			sysACL = append(sysACL, strings.Join([]string{e.Type, e.Qualifier, e.Permissions}, ":"))
+		}
+	}
+	return strings.Join(sysACL, acl.ShortTextForm)
+}
+
+// GetAttrMap fetches all the attributes of a resource with a single
+// GetAttrs call and returns them keyed by their fully qualified key.
+func GetAttrMap(ctx context.Context, c EOSClient, auth Authorization, path string) (map[string]*Attribute, error) {
+	attrs, err := c.GetAttrs(ctx, auth, path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]*Attribute, len(attrs))
+	for _, a := range attrs {
+		m[a.GetKey()] = a
+	}
+	return m, nil
+}
+
+// FilterAttrsByType returns the subset of attrs having the given type.
+func FilterAttrsByType(attrs map[string]*Attribute, t AttrType) map[string]*Attribute {
+	filtered := make(map[string]*Attribute)
+	for k, a := range attrs {
+		if a.Type == t {
+			filtered[k] = a
+		}
+	}
+	return filtered
+}
+
+// CopyAttrs copies the attributes of the given type from srcPath to dstPath,
+// setting all of them with a single SetAttrs call.
+// Per-user favorites are not copied, as they are not inherited by a copy.
+func CopyAttrs(ctx context.Context, c EOSClient, auth Authorization, srcPath, dstPath string, t AttrType) error {
+	attrs, err := c.GetAttrs(ctx, auth, srcPath)
+	if err != nil {
+		return err
+	}
+
+	toCopy := make([]*Attribute, 0, len(attrs))
+	for _, a := range attrs {
+		if a.Type == t && !(a.Type == UserAttr && a.Key == FavoritesKey) {
+			toCopy = append(toCopy, a)
+		}
+	}
+	if len(toCopy) == 0 {
+		return nil
+	}
+	return c.SetAttrs(ctx, auth, toCopy, false, false, dstPath, "")
+}
+
+// UnsetAttr removes the given attribute from the resource at path only,
+// i.e. not recursively and without any app label, addressing it by its
+// fully qualified key as given by GetKey. Removing an attribute that
+// is not set is not considered an error.
+func UnsetAttr(ctx context.Context, c EOSClient, auth Authorization, attr *Attribute, path string) error {
+	err := c.UnsetAttr(ctx, auth, attr, false, path, "")
+	if errors.Is(err, AttrNotExistsError) {
+		return nil
+	}
+	return err
+}
+
+// DaemonUID and DaemonGID identify the EOS daemon account.
+const (
+	DaemonUID = "2"
+	DaemonGID = "2"
+)
+
 func GetDaemonAuth() Authorization {
-	return Authorization{Role: Role{UID: "2", GID: "2"}}
+	return Authorization{Role: Role{UID: DaemonUID, GID: DaemonGID}}
+}
+
+// IsDaemonAuth returns whether the auth object maps to the daemon account.
+func IsDaemonAuth(a Authorization) bool {
+	return a.Token == "" && a.Role.UID == DaemonUID && a.Role.GID == DaemonGID
 }
 
 // This function is used when we don't want to pass any additional auth info.
@@ -73,29 +230,79 @@ func GetEmptyAuth() Authorization {
 	return Authorization{}
 }
 
+// IsEmptyAuth returns whether the auth object carries no auth info,
+// i.e. it will be mapped to the cbox account.
+func IsEmptyAuth(a Authorization) bool {
+	return a.Token == "" && a.Role == Role{}
+}
+
+// GetTokenAuth returns an auth object that maps to the owner of
+// the given EOS token, without going through the cbox account.
+func GetTokenAuth(token string) Authorization {
+	return Authorization{Token: token}
+}
+
 // Returns the userAuth if this is a valid auth object,
-// otherwise returns daemonAuth
+// i.e. it carries either a token or both uid and gid,
+// otherwise returns daemonAuth targeting the same instance
 func GetUserOrDaemonAuth(userAuth Authorization) Authorization {
+	if userAuth.Token != "" {
+		return userAuth
+	}
 	if userAuth.Role.UID == "" || userAuth.Role.GID == "" {
-		return GetDaemonAuth()
+		return GetDaemonAuth().WithInstance(userAuth.Instance)
 	} else {
 		return userAuth
 	}
 }
 
-// Extract uid and gid from auth object
+// GetUserOrDaemonAuthWithContext behaves like GetUserOrDaemonAuth,
+// additionally logging why the daemon fallback was chosen
+// when enabled with SetLogDaemonFallback.
+func GetUserOrDaemonAuthWithContext(ctx context.Context, userAuth Authorization) Authorization {
+	auth := GetUserOrDaemonAuth(userAuth)
+	if auth != userAuth && logDaemonFallback.Load() {
+		appctx.GetLogger(ctx).Debug().Bool("empty_uid", userAuth.Role.UID == "").Bool("empty_gid", userAuth.Role.GID == "").Msg("eosclient: falling back to daemon auth")
+	}
+	return auth
+}
+
+// Extract uid and gid from auth object,
+// falling back to nobody on parse failure
 func ExtractUidGid(auth Authorization) (uid, gid uint64, err error) {
 	// $ id nobody
 	// uid=65534(nobody) gid=65534(nobody) groups=65534(nobody)
 	nobody := uint64(65534)
 
+	return ExtractUidGidOr(auth, nobody, nobody)
+}
+
+// ExtractUidGidOr extracts uid and gid from auth object, falling back
+// to the given uid and gid on parse failure, as nobody differs across systems
+func ExtractUidGidOr(auth Authorization, fallbackUID, fallbackGID uint64) (uid, gid uint64, err error) {
+	uid, gid, err = ExtractUidGidStrict(auth)
+	if err != nil {
+		return fallbackUID, fallbackGID, err
+	}
+	return uid, gid, nil
+}
+
+// InvalidID is the uid and gid returned by ExtractUidGidStrict on parse failure.
+// It is (uid_t)-1 once truncated, which does not identify any account.
+const InvalidID = math.MaxUint64
+
+// ExtractUidGidStrict extracts uid and gid from auth object, without
+// substituting any identity on parse failure.
+// WARNING: on error both uid and gid are InvalidID, never 0, since 0 is root;
+// callers must check err and never use the returned ids when it is set.
+func ExtractUidGidStrict(auth Authorization) (uid, gid uint64, err error) {
 	uid, err = strconv.ParseUint(auth.Role.UID, 10, 64)
 	if err != nil {
-		return nobody, nobody, err
+		return InvalidID, InvalidID, err
 	}
 	gid, err = strconv.ParseUint(auth.Role.GID, 10, 64)
 	if err != nil {
-		return nobody, nobody, err
+		return InvalidID, InvalidID, err
 	}
 
 	return uid, gid, nil
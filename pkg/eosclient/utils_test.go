@@ -0,0 +1,574 @@
+// Copyright 2018-2025 CERN
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// In applying this license, CERN does not waive the privileges and immunities
+// granted to it by virtue of its status as an Intergovernmental Organization
+// or submit itself to any jurisdiction.
+
+package eosclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cs3org/reva/pkg/appctx"
+	"github.com/cs3org/reva/pkg/storage/utils/acl"
+	"github.com/rs/zerolog"
+)
+
+func TestGetUserOrDaemonAuthKeepsInstance(t *testing.T) {
+	tests := map[string]struct {
+		auth     Authorization
+		expected Authorization
+	}{
+		"user auth": {
+			auth:     Authorization{Role: Role{UID: "1000", GID: "1000"}}.WithInstance("eoshome"),
+			expected: Authorization{Role: Role{UID: "1000", GID: "1000"}, Instance: "eoshome"},
+		},
+		"token auth": {
+			auth:     GetTokenAuth("zteos64:token").WithInstance("eosproject"),
+			expected: Authorization{Token: "zteos64:token", Instance: "eosproject"},
+		},
+		"daemon fallback": {
+			auth:     GetEmptyAuth().WithInstance("eosuser"),
+			expected: Authorization{Role: Role{UID: DaemonUID, GID: DaemonGID}, Instance: "eosuser"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := GetUserOrDaemonAuth(tt.auth)
+			if got != tt.expected {
+				t.Fatalf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseAttribute(t *testing.T) {
+	tests := map[string]*Attribute{
+		"sys.acl":                  {Type: SystemAttr, Key: "acl"},
+		"sys.forced.checksum":      {Type: SystemAttr, Key: "forced.checksum"},
+		"user.ocis.foo":            {Type: UserAttr, Key: "ocis.foo"},
+		"user.http://owncloud.org": {Type: UserAttr, Key: "http://owncloud.org"},
+	}
+
+	for full, expected := range tests {
+		t.Run(full, func(t *testing.T) {
+			got, err := ParseAttribute(full)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != *expected {
+				t.Fatalf("expected %+v, got %+v", expected, got)
+			}
+			if got.GetKey() != full {
+				t.Fatalf("expected %s to round-trip, got %s", full, got.GetKey())
+			}
+		})
+	}
+}
+
+func TestParseAttributeErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":             "",
+		"no namespace":      "acl",
+		"empty key":         "sys.",
+		"unknown namespace": "foo.bar",
+	}
+
+	for name, full := range tests {
+		t.Run(name, func(t *testing.T) {
+			if a, err := ParseAttribute(full); err == nil {
+				t.Fatalf("expected error, got %+v", a)
+			}
+		})
+	}
+}
+
+func TestGetUserOrDaemonAuth(t *testing.T) {
+	tests := map[string]struct {
+		auth     Authorization
+		expected Authorization
+	}{
+		"token": {
+			auth:     GetTokenAuth("zteos64:token"),
+			expected: Authorization{Token: "zteos64:token"},
+		},
+		"token with partial role": {
+			auth:     Authorization{Token: "zteos64:token", Role: Role{UID: "1000"}},
+			expected: Authorization{Token: "zteos64:token", Role: Role{UID: "1000"}},
+		},
+		"token only on instance": {
+			auth:     Authorization{Token: "zteos64:token", Instance: "eoshome"},
+			expected: Authorization{Token: "zteos64:token", Instance: "eoshome"},
+		},
+		"uid and gid": {
+			auth:     Authorization{Role: Role{UID: "1000", GID: "1000"}},
+			expected: Authorization{Role: Role{UID: "1000", GID: "1000"}},
+		},
+		"missing gid": {
+			auth:     Authorization{Role: Role{UID: "1000"}},
+			expected: GetDaemonAuth(),
+		},
+		"empty": {
+			auth:     GetEmptyAuth(),
+			expected: GetDaemonAuth(),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := GetUserOrDaemonAuth(tt.auth)
+			if got != tt.expected {
+				t.Fatalf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExtractUidGid(t *testing.T) {
+	tests := map[string]struct {
+		auth        Authorization
+		uid, gid    uint64
+		expectError bool
+	}{
+		"numeric": {
+			auth: Authorization{Role: Role{UID: "1000", GID: "1001"}},
+			uid:  1000,
+			gid:  1001,
+		},
+		"empty": {
+			auth:        GetEmptyAuth(),
+			expectError: true,
+		},
+		"non numeric uid": {
+			auth:        Authorization{Role: Role{UID: "einstein", GID: "1001"}},
+			expectError: true,
+		},
+		"non numeric gid": {
+			auth:        Authorization{Role: Role{UID: "1000", GID: "physics"}},
+			expectError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			uid, gid, err := ExtractUidGidStrict(tt.auth)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got %d:%d", uid, gid)
+				}
+				if uid != InvalidID || gid != InvalidID {
+					t.Fatalf("expected invalid ids on error, got %d:%d", uid, gid)
+				}
+			} else if err != nil || uid != tt.uid || gid != tt.gid {
+				t.Fatalf("expected %d:%d, got %d:%d (err: %v)", tt.uid, tt.gid, uid, gid, err)
+			}
+
+			uid, gid, err = ExtractUidGid(tt.auth)
+			if tt.expectError {
+				if err == nil || uid != 65534 || gid != 65534 {
+					t.Fatalf("expected nobody and error, got %d:%d (err: %v)", uid, gid, err)
+				}
+			} else if err != nil || uid != tt.uid || gid != tt.gid {
+				t.Fatalf("expected %d:%d, got %d:%d (err: %v)", tt.uid, tt.gid, uid, gid, err)
+			}
+
+			uid, gid, err = ExtractUidGidOr(tt.auth, 99, 99)
+			if tt.expectError {
+				if err == nil || uid != 99 || gid != 99 {
+					t.Fatalf("expected fallback and error, got %d:%d (err: %v)", uid, gid, err)
+				}
+			} else if err != nil || uid != tt.uid || gid != tt.gid {
+				t.Fatalf("expected %d:%d, got %d:%d (err: %v)", tt.uid, tt.gid, uid, gid, err)
+			}
+		})
+	}
+}
+
+// fakeClient implements the EOSClient methods exercised by the helpers,
+// recording the calls it receives. Any other method panics.
+type fakeClient struct {
+	EOSClient
+	attrs      []*Attribute
+	unsetErr   error
+	getAttrs   int
+	setCalls   [][]*Attribute
+	unsetCalls []*Attribute
+}
+
+func (c *fakeClient) SetAttrs(ctx context.Context, auth Authorization, attrs []*Attribute, errorIfExists, recursive bool, path, app string) error {
+	c.setCalls = append(c.setCalls, attrs)
+	return nil
+}
+
+func (c *fakeClient) GetAttrs(ctx context.Context, auth Authorization, path string) ([]*Attribute, error) {
+	c.getAttrs++
+	return c.attrs, nil
+}
+
+func (c *fakeClient) UnsetAttr(ctx context.Context, auth Authorization, attr *Attribute, recursive bool, path, app string) error {
+	c.unsetCalls = append(c.unsetCalls, attr)
+	return c.unsetErr
+}
+
+func TestGetAttrMap(t *testing.T) {
+	c := &fakeClient{attrs: []*Attribute{
+		{Type: SystemAttr, Key: "acl", Val: "u:1000=rwx"},
+		{Type: SystemAttr, Key: "forced.checksum", Val: "adler"},
+		{Type: UserAttr, Key: "ocis.foo", Val: "bar"},
+	}}
+
+	attrs, err := GetAttrMap(context.Background(), c, GetEmptyAuth(), "/eos/user/e/einstein/file")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.getAttrs != 1 {
+		t.Fatalf("expected a single GetAttrs call, got %d", c.getAttrs)
+	}
+	if len(attrs) != 3 || attrs["sys.acl"].Val != "u:1000=rwx" || attrs["sys.forced.checksum"].Val != "adler" || attrs["user.ocis.foo"].Val != "bar" {
+		t.Fatalf("unexpected attributes: %+v", attrs)
+	}
+
+	sys := FilterAttrsByType(attrs, SystemAttr)
+	if len(sys) != 2 || sys["sys.acl"] == nil || sys["sys.forced.checksum"] == nil {
+		t.Fatalf("unexpected system attributes: %+v", sys)
+	}
+	user := FilterAttrsByType(attrs, UserAttr)
+	if len(user) != 1 || user["user.ocis.foo"] == nil {
+		t.Fatalf("unexpected user attributes: %+v", user)
+	}
+}
+
+func TestGetUserOrDaemonAuthWithContextLogsFallback(t *testing.T) {
+	SetLogDaemonFallback(true)
+	defer SetLogDaemonFallback(false)
+
+	tests := map[string]struct {
+		auth   Authorization
+		logged string
+	}{
+		"user auth":  {auth: Authorization{Role: Role{UID: "1000", GID: "1000"}}},
+		"token auth": {auth: GetTokenAuth("zteos64:token")},
+		"missing gid": {
+			auth:   Authorization{Role: Role{UID: "1000"}},
+			logged: `"empty_uid":false,"empty_gid":true`,
+		},
+		"empty": {
+			auth:   GetEmptyAuth(),
+			logged: `"empty_uid":true,"empty_gid":true`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := zerolog.New(&buf).Level(zerolog.DebugLevel)
+			ctx := appctx.WithLogger(context.Background(), &log)
+
+			got := GetUserOrDaemonAuthWithContext(ctx, tt.auth)
+			if got != GetUserOrDaemonAuth(tt.auth) {
+				t.Fatalf("expected same auth as GetUserOrDaemonAuth, got %+v", got)
+			}
+
+			out := buf.String()
+			if tt.logged == "" {
+				if out != "" {
+					t.Fatalf("expected no log, got %s", out)
+				}
+				return
+			}
+			if !strings.Contains(out, "falling back to daemon auth") || !strings.Contains(out, tt.logged) {
+				t.Fatalf("expected fallback log with %s, got %s", tt.logged, out)
+			}
+		})
+	}
+}
+
+func TestGetUserOrDaemonAuthWithContextLoggingDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf).Level(zerolog.DebugLevel)
+	ctx := appctx.WithLogger(context.Background(), &log)
+
+	GetUserOrDaemonAuthWithContext(ctx, GetEmptyAuth())
+	if buf.Len() != 0 {
+		t.Fatalf("expected no log when disabled, got %s", buf.String())
+	}
+}
+
+func TestParseACL(t *testing.T) {
+	tests := map[string]struct {
+		sysACL   string
+		expected []acl.Entry
+	}{
+		"empty": {
+			sysACL:   "",
+			expected: []acl.Entry{},
+		},
+		"stored form": {
+			sysACL: "u:1000:rwx,egroup:cernbox-admins:rx",
+			expected: []acl.Entry{
+				{Type: acl.TypeUser, Qualifier: "1000", Permissions: "rwx"},
+				{Type: acl.TypeGroup, Qualifier: "cernbox-admins", Permissions: "rx"},
+			},
+		},
+		"written by AddACL": {
+			sysACL: "u:1000=rwx,egroup:cernbox-admins=rx",
+			expected: []acl.Entry{
+				{Type: acl.TypeUser, Qualifier: "1000", Permissions: "rwx"},
+				{Type: acl.TypeGroup, Qualifier: "cernbox-admins", Permissions: "rx"},
+			},
+		},
+		"deny markers": {
+			sysACL: "u:1000:rwx!d,u:1001:rx!u,egroup:it-dep:!r!w!x",
+			expected: []acl.Entry{
+				{Type: acl.TypeUser, Qualifier: "1000", Permissions: "rwx!d"},
+				{Type: acl.TypeUser, Qualifier: "1001", Permissions: "rx!u"},
+				{Type: acl.TypeGroup, Qualifier: "it-dep", Permissions: "!r!w!x"},
+			},
+		},
+		"allow marker and unix group": {
+			sysACL: "g:2763:rwx+d,u:1000:rwxm",
+			expected: []acl.Entry{
+				{Type: "g", Qualifier: "2763", Permissions: "rwx+d"},
+				{Type: acl.TypeUser, Qualifier: "1000", Permissions: "rwxm"},
+			},
+		},
+		"lightweight": {
+			sysACL: "lw:guest@example.org=rx,u:1000:rwx",
+			expected: []acl.Entry{
+				{Type: acl.TypeLightweight, Qualifier: "guest@example.org", Permissions: "rx"},
+				{Type: acl.TypeUser, Qualifier: "1000", Permissions: "rwx"},
+			},
+		},
+		"other": {
+			sysACL: "u:1000:rwx,z:rx",
+			expected: []acl.Entry{
+				{Type: acl.TypeUser, Qualifier: "1000", Permissions: "rwx"},
+				{Type: ACLTypeOther, Permissions: "rx"},
+			},
+		},
+		"unknown type": {
+			sysACL: "k:1234:r",
+			expected: []acl.Entry{
+				{Type: "k", Qualifier: "1234", Permissions: "r"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseACL(tt.sysACL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertACLEntries(t, got, tt.expected)
+
+			// the serialized form must parse back to the same entries
+			reparsed, err := ParseACL(SerializeACL(got))
+			if err != nil {
+				t.Fatalf("unexpected error parsing %s: %v", SerializeACL(got), err)
+			}
+			assertACLEntries(t, reparsed, tt.expected)
+		})
+	}
+}
+
+func TestSerializeACL(t *testing.T) {
+	tests := map[string]string{
+		"stored form":       "u:1000:rwx!d,egroup:cernbox-admins:rx,z:rx",
+		"lightweight entry": "u:1000:rwx,lw:einstein=rx",
+		"empty":             "",
+	}
+
+	for name, sysACL := range tests {
+		t.Run(name, func(t *testing.T) {
+			a, err := ParseACL(sysACL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := SerializeACL(a); got != sysACL {
+				t.Fatalf("expected %s, got %s", sysACL, got)
+			}
+		})
+	}
+}
+
+func TestParseACLErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing permissions": "u:1000",
+		"too many fields":     "u:1000:rwx:extra",
+		"malformed entry":     "u:1000:rwx,garbage",
+		"empty z permissions": "z:",
+	}
+
+	for name, sysACL := range tests {
+		t.Run(name, func(t *testing.T) {
+			if a, err := ParseACL(sysACL); err == nil {
+				t.Fatalf("expected error, got %+v", a.Entries)
+			}
+		})
+	}
+}
+
+func assertACLEntries(t *testing.T, got *acl.ACLs, expected []acl.Entry) {
+	t.Helper()
+	if len(got.Entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expected), len(got.Entries), got.Entries)
+	}
+	for i, e := range got.Entries {
+		if *e != expected[i] {
+			t.Fatalf("expected entry %d to be %+v, got %+v", i, expected[i], *e)
+		}
+	}
+}
+
+func TestAttrTypeConversion(t *testing.T) {
+	tests := map[string]AttrType{
+		"sys":     SystemAttr,
+		"user":    UserAttr,
+		"trusted": TrustedAttr,
+	}
+
+	for s, at := range tests {
+		t.Run(s, func(t *testing.T) {
+			got, err := AttrStringToType(s)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != at {
+				t.Fatalf("expected %d, got %d", at, got)
+			}
+			if AttrTypeToString(at) != s {
+				t.Fatalf("expected %s, got %s", s, AttrTypeToString(at))
+			}
+			a := &Attribute{Type: at, Key: "ocis.foo"}
+			if a.GetKey() != s+".ocis.foo" {
+				t.Fatalf("expected %s.ocis.foo, got %s", s, a.GetKey())
+			}
+		})
+	}
+
+	if _, err := AttrStringToType("security"); err == nil {
+		t.Fatal("expected error for unknown attr type")
+	}
+	if AttrTypeToString(AttrType(42)) != "invalid" {
+		t.Fatalf("expected invalid, got %s", AttrTypeToString(AttrType(42)))
+	}
+}
+
+func TestAttributeIsValid(t *testing.T) {
+	tests := map[string]struct {
+		attr  Attribute
+		valid bool
+	}{
+		"sys attr":     {Attribute{Type: SystemAttr, Key: "acl"}, true},
+		"user attr":    {Attribute{Type: UserAttr, Key: "ocis.foo"}, true},
+		"trusted attr": {Attribute{Type: TrustedAttr, Key: "ocis.blobid"}, true},
+		"empty key":    {Attribute{Type: TrustedAttr}, false},
+		"unknown type": {Attribute{Type: AttrType(42), Key: "foo"}, false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.attr.IsValid(); got != tt.valid {
+				t.Fatalf("expected %t, got %t", tt.valid, got)
+			}
+		})
+	}
+}
+
+func TestIsDaemonOrEmptyAuth(t *testing.T) {
+	tests := map[string]struct {
+		auth          Authorization
+		daemon, empty bool
+	}{
+		"daemon":                 {auth: GetDaemonAuth(), daemon: true},
+		"daemon on instance":     {auth: GetDaemonAuth().WithInstance("eoshome"), daemon: true},
+		"empty":                  {auth: GetEmptyAuth(), empty: true},
+		"empty on instance":      {auth: GetEmptyAuth().WithInstance("eoshome"), empty: true},
+		"user":                   {auth: Authorization{Role: Role{UID: "1000", GID: "1000"}}},
+		"daemon uid only":        {auth: Authorization{Role: Role{UID: DaemonUID, GID: "1000"}}},
+		"token":                  {auth: GetTokenAuth("zteos64:token")},
+		"daemon role with token": {auth: Authorization{Role: Role{UID: DaemonUID, GID: DaemonGID}, Token: "zteos64:token"}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsDaemonAuth(tt.auth); got != tt.daemon {
+				t.Fatalf("expected IsDaemonAuth to be %t, got %t", tt.daemon, got)
+			}
+			if got := IsEmptyAuth(tt.auth); got != tt.empty {
+				t.Fatalf("expected IsEmptyAuth to be %t, got %t", tt.empty, got)
+			}
+		})
+	}
+}
+
+func TestCopyAttrs(t *testing.T) {
+	c := &fakeClient{attrs: []*Attribute{
+		{Type: SystemAttr, Key: "acl", Val: "u:1000=rwx"},
+		{Type: UserAttr, Key: "ocis.foo", Val: "bar"},
+		{Type: UserAttr, Key: "ocis.baz", Val: "qux"},
+		{Type: UserAttr, Key: FavoritesKey, Val: "u:einstein=1"},
+	}}
+
+	if err := CopyAttrs(context.Background(), c, GetEmptyAuth(), "/eos/src", "/eos/dst", UserAttr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.setCalls) != 1 {
+		t.Fatalf("expected a single SetAttrs call, got %d", len(c.setCalls))
+	}
+	copied := c.setCalls[0]
+	if len(copied) != 2 || copied[0].GetKey() != "user.ocis.foo" || copied[1].GetKey() != "user.ocis.baz" {
+		t.Fatalf("unexpected copied attributes: %+v", copied)
+	}
+}
+
+func TestUnsetAttr(t *testing.T) {
+	tests := map[string]struct {
+		unsetErr error
+		expected error
+	}{
+		"set":     {},
+		"not set": {unsetErr: AttrNotExistsError},
+		"not set, wrapped": {
+			unsetErr: fmt.Errorf("eos: %w", AttrNotExistsError),
+		},
+		"other error": {
+			unsetErr: FileIsLockedError,
+			expected: FileIsLockedError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &fakeClient{unsetErr: tt.unsetErr}
+			attr := &Attribute{Type: TrustedAttr, Key: "ocis.foo"}
+
+			err := UnsetAttr(context.Background(), c, GetEmptyAuth(), attr, "/eos/file")
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+			if len(c.unsetCalls) != 1 || c.unsetCalls[0].GetKey() != "trusted.ocis.foo" {
+				t.Fatalf("unexpected unset calls: %+v", c.unsetCalls)
+			}
+		})
+	}
+}
@@ -20,8 +20,10 @@ package grants
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 
+	userpb "github.com/cs3org/go-cs3apis/cs3/identity/user/v1beta1"
 	provider "github.com/cs3org/go-cs3apis/cs3/storage/provider/v1beta1"
 	"github.com/cs3org/reva/pkg/storage/utils/acl"
 	"google.golang.org/protobuf/proto"
@@ -116,6 +118,34 @@ func GetACLType(gt provider.GranteeType) (string, error) {
 	}
 }
 
+// GetUserACLEntry builds the EOS ACL entry granting the given permissions to a user.
+// Lightweight and federated accounts have no uid in EOS, so they are referenced
+// by their opaque id with the lightweight prefix; all other accounts are
+// referenced by their uid, as EOS Citrine stores user ACLs by uid.
+func GetUserACLEntry(u *userpb.User, permissions string) (*acl.Entry, error) {
+	if u == nil || u.Id == nil {
+		return nil, errors.New("no eos acl for user without id")
+	}
+
+	switch u.Id.Type {
+	case userpb.UserType_USER_TYPE_LIGHTWEIGHT, userpb.UserType_USER_TYPE_FEDERATED:
+		return &acl.Entry{
+			Type:        acl.TypeLightweight,
+			Qualifier:   u.Id.OpaqueId,
+			Permissions: permissions,
+		}, nil
+	default:
+		if u.UidNumber <= 0 {
+			return nil, errors.New("no eos acl for user without uid: " + u.Id.OpaqueId)
+		}
+		return &acl.Entry{
+			Type:        acl.TypeUser,
+			Qualifier:   strconv.FormatInt(u.UidNumber, 10),
+			Permissions: permissions,
+		}, nil
+	}
+}
+
 // GetGranteeType returns the grantee type from a char.
 func GetGranteeType(aclType string) provider.GranteeType {
 	switch aclType {
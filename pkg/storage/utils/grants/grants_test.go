@@ -0,0 +1,83 @@
+// Copyright 2018-2025 CERN
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// In applying this license, CERN does not waive the privileges and immunities
+// granted to it by virtue of its status as an Intergovernmental Organization
+// or submit itself to any jurisdiction.
+
+package grants
+
+import (
+	"testing"
+
+	userpb "github.com/cs3org/go-cs3apis/cs3/identity/user/v1beta1"
+	"github.com/cs3org/reva/pkg/storage/utils/acl"
+)
+
+func TestGetUserACLEntry(t *testing.T) {
+	tests := map[string]struct {
+		user     *userpb.User
+		expected *acl.Entry
+	}{
+		"primary": {
+			user: &userpb.User{
+				Id:        &userpb.UserId{OpaqueId: "einstein", Type: userpb.UserType_USER_TYPE_PRIMARY},
+				UidNumber: 1000,
+			},
+			expected: &acl.Entry{Type: acl.TypeUser, Qualifier: "1000", Permissions: "rx"},
+		},
+		"lightweight": {
+			user: &userpb.User{
+				Id: &userpb.UserId{OpaqueId: "guest@example.org", Type: userpb.UserType_USER_TYPE_LIGHTWEIGHT},
+			},
+			expected: &acl.Entry{Type: acl.TypeLightweight, Qualifier: "guest@example.org", Permissions: "rx"},
+		},
+		"federated": {
+			user: &userpb.User{
+				Id: &userpb.UserId{OpaqueId: "marie@cern.ch", Type: userpb.UserType_USER_TYPE_FEDERATED},
+			},
+			expected: &acl.Entry{Type: acl.TypeLightweight, Qualifier: "marie@cern.ch", Permissions: "rx"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := GetUserACLEntry(tt.user, "rx")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != *tt.expected {
+				t.Fatalf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetUserACLEntryErrors(t *testing.T) {
+	tests := map[string]*userpb.User{
+		"nil user":    nil,
+		"nil user id": {},
+		"primary without uid": {
+			Id: &userpb.UserId{OpaqueId: "einstein", Type: userpb.UserType_USER_TYPE_PRIMARY},
+		},
+	}
+
+	for name, u := range tests {
+		t.Run(name, func(t *testing.T) {
+			if e, err := GetUserACLEntry(u, "rx"); err == nil {
+				t.Fatalf("expected error, got %+v", e)
+			}
+		})
+	}
+}